@@ -0,0 +1,53 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Column is one row read from information_schema.columns.
+type Column struct {
+	Name     string
+	DataType string
+	Nullable bool
+}
+
+// fetchColumns introspects the given table's columns via
+// information_schema, in ordinal position order, so the generated struct's
+// field order matches the table's column order.
+func fetchColumns(db *sql.DB, dialect, schema, table string) ([]Column, error) {
+	placeholder1, placeholder2 := "?", "?"
+	if dialect == "postgres" {
+		placeholder1, placeholder2 = "$1", "$2"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT column_name, data_type, is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = %s AND table_name = %s
+		ORDER BY ordinal_position`, placeholder1, placeholder2)
+
+	rows, err := db.Query(query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("modelgen: query information_schema.columns for %s.%s: %w", schema, table, err)
+	}
+	defer rows.Close()
+
+	var cols []Column
+	for rows.Next() {
+		var c Column
+		var nullable string
+		if err := rows.Scan(&c.Name, &c.DataType, &nullable); err != nil {
+			return nil, fmt.Errorf("modelgen: scan column: %w", err)
+		}
+		c.Nullable = nullable == "YES"
+		cols = append(cols, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("modelgen: table %s.%s has no columns (does it exist?)", schema, table)
+	}
+	return cols, nil
+}
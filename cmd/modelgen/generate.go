@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// field is the template data for one generated struct field.
+type field struct {
+	GoName  string
+	GoType  string
+	DBTag   string
+	JSONTag string
+}
+
+// model is the template data for one generated struct.
+type model struct {
+	Package string
+	Name    string
+	Fields  []field
+}
+
+var modelTemplate = template.Must(template.New("model").Parse(`// Code generated by modelgen. DO NOT EDIT.
+
+package {{.Package}}
+
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.GoName}} {{.GoType}} ` + "`" + `db:"{{.DBTag}}" json:"{{.JSONTag}}"` + "`" + `
+{{- end}}
+}
+`))
+
+// defaultTypeMap covers the information_schema.columns data_type values
+// this repo's tables use; -type flags layer overrides on top of it.
+var defaultTypeMap = map[string]string{
+	"bigint":    "int64",
+	"int":       "int32",
+	"smallint":  "int16",
+	"tinyint":   "int8",
+	"varchar":   "string",
+	"char":      "string",
+	"text":      "string",
+	"boolean":   "bool",
+	"bool":      "bool",
+	"timestamp": "time.Time",
+	"datetime":  "time.Time",
+	"date":      "time.Time",
+	"decimal":   "float64",
+	"numeric":   "float64",
+	"double":    "float64",
+	"float":     "float32",
+
+	// Postgres reports VARCHAR and TIMESTAMP columns under these names.
+	"character varying":           "string",
+	"timestamp without time zone": "time.Time",
+}
+
+// nullableTypeMap maps a defaultTypeMap Go type to the type used when the
+// column is nullable, so e.g. a nullable varchar becomes sql.NullString
+// rather than string. Go types with no entry here become pointers.
+var nullableTypeMap = map[string]string{
+	"int64":   "sql.NullInt64",
+	"int32":   "sql.NullInt32",
+	"string":  "sql.NullString",
+	"bool":    "sql.NullBool",
+	"float64": "sql.NullFloat64",
+
+	"time.Time": "sql.NullTime",
+}
+
+// goType resolves a column's information_schema data_type (and nullability)
+// to a Go type, applying user-supplied overrides before falling back to
+// defaultTypeMap/nullableTypeMap. An override is used as-is, whether or not
+// the column is nullable.
+func goType(dataType string, nullable bool, overrides map[string]string) string {
+	if override, ok := overrides[dataType]; ok {
+		return override
+	}
+	base, ok := defaultTypeMap[dataType]
+	if !ok {
+		base = "interface{}"
+	}
+
+	if !nullable {
+		return base
+	}
+	if nullType, ok := nullableTypeMap[base]; ok {
+		return nullType
+	}
+	return "*" + base
+}
+
+// usesSQLPackage reports whether any field's type came from database/sql,
+// i.e. whether the generated file needs that import.
+func usesSQLPackage(fields []field) bool {
+	for _, f := range fields {
+		if strings.HasPrefix(f.GoType, "sql.") {
+			return true
+		}
+	}
+	return false
+}
+
+// usesTimePackage reports whether any field's type is time.Time.
+func usesTimePackage(fields []field) bool {
+	for _, f := range fields {
+		if f.GoType == "time.Time" {
+			return true
+		}
+	}
+	return false
+}
+
+// generate renders the Go source for a struct named goName describing
+// table, using cols and the given type overrides.
+func generate(pkg, goName string, cols []Column, overrides map[string]string) ([]byte, error) {
+	m := model{Package: pkg, Name: goName}
+	for _, c := range cols {
+		m.Fields = append(m.Fields, field{
+			GoName:  toGoName(c.Name),
+			GoType:  goType(c.DataType, c.Nullable, overrides),
+			DBTag:   c.Name,
+			JSONTag: c.Name,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := modelTemplate.Execute(&buf, m); err != nil {
+		return nil, fmt.Errorf("modelgen: render template: %w", err)
+	}
+
+	src := buf.String()
+	var imports []string
+	if usesTimePackage(m.Fields) {
+		imports = append(imports, `"time"`)
+	}
+	if usesSQLPackage(m.Fields) {
+		imports = append(imports, `"database/sql"`)
+	}
+	if len(imports) > 0 {
+		src = strings.Replace(src, fmt.Sprintf("package %s\n", pkg),
+			fmt.Sprintf("package %s\n\nimport (\n\t%s\n)\n", pkg, strings.Join(imports, "\n\t")), 1)
+	}
+
+	return []byte(src), nil
+}
+
+// initialisms are common acronyms that Go style capitalizes in full (ID,
+// not Id), matching this repo's existing User.ID field.
+var initialisms = map[string]string{
+	"id":  "ID",
+	"url": "URL",
+}
+
+// toGoName converts a snake_case column name (e.g. "phone_number") to an
+// exported Go identifier ("PhoneNumber").
+func toGoName(column string) string {
+	parts := strings.Split(column, "_")
+	var b strings.Builder
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		if upper, ok := initialisms[strings.ToLower(p)]; ok {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToGoName(t *testing.T) {
+	require.Equal(t, "PhoneNumber", toGoName("phone_number"))
+	require.Equal(t, "ID", toGoName("id"))
+	require.Equal(t, "FullName", toGoName("full_name"))
+}
+
+func TestGoType(t *testing.T) {
+	require.Equal(t, "string", goType("varchar", false, nil))
+	require.Equal(t, "sql.NullString", goType("varchar", true, nil))
+	require.Equal(t, "time.Time", goType("timestamp", false, nil))
+	require.Equal(t, "sql.NullTime", goType("timestamp", true, nil))
+	require.Equal(t, "interface{}", goType("bytea", false, nil))
+}
+
+func TestGoType_overrides(t *testing.T) {
+	overrides := typeOverrides{"varchar": "*string"}
+	require.Equal(t, "*string", goType("varchar", false, overrides))
+}
+
+func TestGenerate(t *testing.T) {
+	cols := []Column{
+		{Name: "id", DataType: "bigint"},
+		{Name: "phone_number", DataType: "varchar", Nullable: true},
+		{Name: "created_at", DataType: "timestamp"},
+	}
+
+	src, err := generate("models", "User", cols, nil)
+	require.NoError(t, err)
+
+	out := string(src)
+	require.Contains(t, out, "type User struct")
+	require.Contains(t, out, `ID int64 `+"`db:\"id\" json:\"id\"`")
+	require.Contains(t, out, `PhoneNumber sql.NullString `+"`db:\"phone_number\" json:\"phone_number\"`")
+	require.True(t, strings.Contains(out, `"database/sql"`))
+	require.True(t, strings.Contains(out, `"time"`))
+}
+
+func TestSingularize(t *testing.T) {
+	require.Equal(t, "user", singularize("users"))
+	require.Equal(t, "address", singularize("address"))
+}
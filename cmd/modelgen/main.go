@@ -0,0 +1,142 @@
+// Command modelgen introspects a table via information_schema.columns and
+// emits a Go struct with sqlx-compatible `db` and `json` tags, so models
+// like the User struct in this repo's tests never drift from the live
+// schema the way it did when phone_number was added without regenerating
+// it (see TestMigrationIssue_sqlx_failure).
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+// typeOverrides implements flag.Value for repeatable -type flags of the
+// form "data_type=go_type", e.g. -type "varchar=*string".
+type typeOverrides map[string]string
+
+func (o typeOverrides) String() string {
+	var parts []string
+	for k, v := range o {
+		parts = append(parts, k+"="+v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (o typeOverrides) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("modelgen: -type must be data_type=go_type, got %q", s)
+	}
+	o[k] = v
+	return nil
+}
+
+func main() {
+	var (
+		dsn     = flag.String("dsn", "", "database DSN to connect to")
+		dialect = flag.String("dialect", "mysql", "mysql or postgres")
+		schema  = flag.String("schema", "public", "schema to introspect (ignored for mysql, which uses the DSN's database name)")
+		tables  = flag.String("table", "", "comma-separated list of tables to generate structs for")
+		pkg     = flag.String("package", "main", "package name for generated files")
+		out     = flag.String("out", ".", "output directory for generated files")
+		strct   = flag.String("struct", "", "Go struct name to use; only valid with a single -table")
+		check   = flag.Bool("check", false, "fail instead of writing if the on-disk struct would change")
+	)
+	overrides := typeOverrides{}
+	flag.Var(overrides, "type", "override a data_type -> go_type mapping, e.g. -type varchar=*string (repeatable)")
+	flag.Parse()
+
+	if *dsn == "" || *tables == "" {
+		fmt.Fprintln(os.Stderr, "modelgen: -dsn and -table are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	tableList := strings.Split(*tables, ",")
+	if *strct != "" && len(tableList) != 1 {
+		log.Fatal("modelgen: -struct can only be used with a single -table")
+	}
+
+	db, err := sql.Open(*dialect, *dsn)
+	if err != nil {
+		log.Fatalf("modelgen: open %s: %v", *dialect, err)
+	}
+	defer db.Close()
+
+	dbSchema := *schema
+	if *dialect == "mysql" {
+		dbSchema = mysqlSchemaFromDSN(*dsn)
+	}
+
+	exitCode := 0
+	for _, table := range tableList {
+		table = strings.TrimSpace(table)
+		goName := *strct
+		if goName == "" {
+			goName = toGoName(singularize(table))
+		}
+
+		cols, err := fetchColumns(db, *dialect, dbSchema, table)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		src, err := generate(*pkg, goName, cols, overrides)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		formatted, err := format.Source(src)
+		if err != nil {
+			log.Fatalf("modelgen: gofmt generated source for %s: %v", table, err)
+		}
+
+		path := filepath.Join(*out, strings.ToLower(table)+"_gen.go")
+		if *check {
+			existing, err := os.ReadFile(path)
+			if err != nil || !bytes.Equal(existing, formatted) {
+				fmt.Fprintf(os.Stderr, "modelgen: %s is out of date with the schema for table %s\n", path, table)
+				exitCode = 1
+			}
+			continue
+		}
+
+		if err := os.WriteFile(path, formatted, 0o644); err != nil {
+			log.Fatalf("modelgen: write %s: %v", path, err)
+		}
+	}
+
+	os.Exit(exitCode)
+}
+
+// mysqlSchemaFromDSN extracts the database name out of a MySQL DSN of the
+// form "user:pass@tcp(host:port)/dbname?params", since MySQL's
+// information_schema.columns.table_schema is the database name rather than
+// a separate schema concept.
+func mysqlSchemaFromDSN(dsn string) string {
+	afterSlash := dsn[strings.LastIndex(dsn, "/")+1:]
+	if i := strings.IndexByte(afterSlash, '?'); i >= 0 {
+		afterSlash = afterSlash[:i]
+	}
+	return afterSlash
+}
+
+// singularize crudely strips a trailing "s" from a table name, e.g.
+// "users" -> "user". It is only used to pick a default struct name; pass
+// -struct to override it for irregular table names.
+func singularize(table string) string {
+	if strings.HasSuffix(table, "s") && !strings.HasSuffix(table, "ss") {
+		return strings.TrimSuffix(table, "s")
+	}
+	return table
+}
@@ -0,0 +1,79 @@
+// Package dbtest starts disposable MySQL/Postgres containers for tests
+// that need a real database, so the same container-start logic isn't
+// duplicated across every package that exercises more than one SQL
+// dialect.
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// StartMySQL starts a disposable MySQL 8 container (database "auth") and
+// returns a DSN for it, tearing the container down when t completes.
+func StartMySQL(t *testing.T) string {
+	ctx := context.Background()
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "mysql:8",
+			ExposedPorts: []string{"3306/tcp"},
+			Env: map[string]string{
+				"MYSQL_ROOT_PASSWORD": "password",
+				"MYSQL_DATABASE":      "auth",
+			},
+			WaitingFor: wait.ForSQL("3306/tcp", "mysql", func(host string, port nat.Port) string {
+				return fmt.Sprintf("root:password@tcp(%s:%s)/auth?charset=utf8mb4&parseTime=True&loc=Local", host, port.Port())
+			}).WithStartupTimeout(2 * time.Minute),
+		},
+		Started: true,
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, container.Terminate(ctx)) })
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MappedPort(ctx, "3306/tcp")
+	require.NoError(t, err)
+
+	return fmt.Sprintf("root:password@tcp(%s:%s)/auth?charset=utf8mb4&parseTime=True&loc=Local", host, port.Port())
+}
+
+// StartPostgres starts a disposable Postgres 15 container (database "auth")
+// and returns a DSN for it, tearing the container down when t completes.
+func StartPostgres(t *testing.T) string {
+	ctx := context.Background()
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:15",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_PASSWORD": "password",
+				"POSTGRES_DB":       "auth",
+			},
+			WaitingFor: wait.ForSQL("5432/tcp", "postgres", func(host string, port nat.Port) string {
+				return fmt.Sprintf("postgres://postgres:password@%s:%s/auth?sslmode=disable", host, port.Port())
+			}).WithStartupTimeout(2 * time.Minute),
+		},
+		Started: true,
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, container.Terminate(ctx)) })
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MappedPort(ctx, "5432/tcp")
+	require.NoError(t, err)
+
+	return fmt.Sprintf("postgres://postgres:password@%s:%s/auth?sslmode=disable", host, port.Port())
+}
@@ -1,20 +1,25 @@
 package main_test
 
 import (
+	"testing"
+	"time"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"testing"
-	"time"
-)
 
-const (
-	mysqlDSN       = "root:password@tcp(127.0.0.1:3306)/auth?charset=utf8mb4&parseTime=True&loc=Local"
-	migrateSQLUp   = `ALTER TABLE users ADD COLUMN phone_number VARCHAR(127);`
-	migrateSQLDown = `ALTER TABLE users DROP COLUMN phone_number;`
+	"github.com/tranngoclam/go-database-migration/pkg/db"
 )
 
+// The go:generate directive below runs modelgen in -check mode against the
+// live users table: it writes nothing, and exits non-zero when users_gen.go
+// in this directory is missing or differs from what the schema generates.
+// It does not rewrite the hand-written User struct here, which is how
+// phone_number was added to the table in TestMigrationIssue_sqlx_failure
+// but never added here:
+//
+//go:generate go run ./cmd/modelgen -dsn "$MYSQL_DSN" -dialect mysql -table users -struct User -package main_test -out . -check
 type User struct {
 	ID        uint64    `json:"id" db:"id"`
 	FullName  string    `json:"full_name" db:"full_name"`
@@ -23,17 +28,12 @@ type User struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
-type (
-	gormDB struct {
-		client *gorm.DB
-	}
-	sqlxDB struct {
-		client *sqlx.DB
-	}
-)
+type sqlxDB struct {
+	client *sqlx.DB
+}
 
-func (db *sqlxDB) ListUsers(unsafe bool) ([]*User, error) {
-	client := db.client
+func (d *sqlxDB) ListUsers(unsafe bool) ([]*User, error) {
+	client := d.client
 	if unsafe {
 		client = client.Unsafe()
 	}
@@ -46,151 +46,160 @@ func (db *sqlxDB) ListUsers(unsafe bool) ([]*User, error) {
 	users := []*User{}
 	for rows.Next() {
 		var user User
-		err := rows.StructScan(&user)
-		if err != nil {
+		if err := rows.StructScan(&user); err != nil {
 			return nil, err
 		}
-
 		users = append(users, &user)
 	}
-
 	return users, nil
 }
 
-func (db *sqlxDB) MigrateUp() error {
-	_, err := db.client.Exec(migrateSQLUp)
-	return err
+func connectSqlx(driverName, dsn string) (*sqlxDB, error) {
+	client, err := sqlx.Connect(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlxDB{client: client}, nil
 }
 
-func (db *sqlxDB) MigrateDown() error {
-	_, err := db.client.Exec(migrateSQLDown)
-	return err
+// TestMigrationIssue_sqlx_failure runs, for every supported dialect, the
+// scenario that motivated this repo: a migration adds a column while old
+// application code is still querying `SELECT *` into a struct that doesn't
+// know about it. With sqlx's default strict mode that query now fails,
+// because sqlx won't silently drop a column the struct has no field for,
+// even though both the old and new binaries must be able to read the table
+// while the migration is in progress.
+func TestMigrationIssue_sqlx_failure(t *testing.T) {
+	for _, b := range backends() {
+		b := b
+		t.Run(string(b.dialect), func(t *testing.T) {
+			dsn := b.start(t)
+
+			driver, ok := db.For(b.dialect)
+			require.True(t, ok)
+
+			sdb, err := connectSqlx(b.driverName, dsn)
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, sdb.client.Close()) })
+			conn := db.Wrap(sdb.client.DB)
+
+			_, err = conn.DB().Exec(b.createTable)
+			require.NoError(t, err)
+			_, err = conn.DB().Exec(`INSERT INTO users (full_name, address) VALUES ('John Doe', 'Singapore')`)
+			require.NoError(t, err)
+
+			// initial schema, unsafe=false: the struct matches the table exactly.
+			users, err := sdb.ListUsers(false)
+			require.NoError(t, err)
+			require.Len(t, users, 1)
+			require.Equal(t, "John Doe", users[0].FullName)
+			require.Equal(t, "Singapore", users[0].Address)
+
+			// migrate up: add phone_number. Both old and new binaries must still
+			// be able to read/write through the table while this is in progress.
+			require.NoError(t, driver.AddColumn(conn, "users", "phone_number", b.phoneNumber))
+			t.Cleanup(func() { require.NoError(t, driver.DropColumn(conn, "users", "phone_number")) })
+
+			// select again, simulate real traffic from the old binary: this fails
+			// because sqlx's default strict mode refuses to scan a row with a
+			// column the destination struct doesn't know about.
+			_, err = sdb.ListUsers(false)
+			require.Error(t, err)
+			require.Equal(t, "missing destination name phone_number in *main_test.User", err.Error())
+		})
+	}
 }
 
-func (db *gormDB) ListUsers() ([]*User, error) {
-	users := []*User{}
-	err := db.client.Debug().Find(&users).Error
-	return users, err
+// TestMigrationIssue_sqlx_success shows the fix for the above: calling
+// Unsafe() tells sqlx to ignore destination columns it doesn't recognize,
+// so the old binary keeps working unmodified while the migration is live.
+func TestMigrationIssue_sqlx_success(t *testing.T) {
+	for _, b := range backends() {
+		b := b
+		t.Run(string(b.dialect), func(t *testing.T) {
+			dsn := b.start(t)
+
+			driver, ok := db.For(b.dialect)
+			require.True(t, ok)
+
+			sdb, err := connectSqlx(b.driverName, dsn)
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, sdb.client.Close()) })
+			conn := db.Wrap(sdb.client.DB)
+
+			_, err = conn.DB().Exec(b.createTable)
+			require.NoError(t, err)
+			_, err = conn.DB().Exec(`INSERT INTO users (full_name, address) VALUES ('John Doe', 'Singapore')`)
+			require.NoError(t, err)
+
+			users, err := sdb.ListUsers(true)
+			require.NoError(t, err)
+			require.Len(t, users, 1)
+			require.Equal(t, "John Doe", users[0].FullName)
+			require.Equal(t, "Singapore", users[0].Address)
+
+			require.NoError(t, driver.AddColumn(conn, "users", "phone_number", b.phoneNumber))
+			t.Cleanup(func() { require.NoError(t, driver.DropColumn(conn, "users", "phone_number")) })
+
+			users, err = sdb.ListUsers(true)
+			require.NoError(t, err)
+			require.Equal(t, "John Doe", users[0].FullName)
+			require.Equal(t, "Singapore", users[0].Address)
+		})
+	}
 }
 
-func (db *gormDB) MigrateUp() error {
-	return db.client.Debug().Exec(migrateSQLUp).Error
+type gormDB struct {
+	client *gorm.DB
 }
 
-func (db *gormDB) MigrateDown() error {
-	return db.client.Debug().Exec(migrateSQLDown).Error
+func (d *gormDB) ListUsers() ([]*User, error) {
+	users := []*User{}
+	err := d.client.Debug().Find(&users).Error
+	return users, err
 }
 
-func connectGorm() (*gormDB, error) {
-	db, err := gorm.Open(mysql.Open(mysqlDSN), &gorm.Config{})
+func connectGorm(dsn string) (*gormDB, error) {
+	client, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
-
-	return &gormDB{client: db}, err
+	return &gormDB{client: client}, nil
 }
 
-func disconnectGorm(db *gormDB) error {
-	sqlDB, err := db.client.DB()
+func disconnectGorm(d *gormDB) error {
+	sqlDB, err := d.client.DB()
 	if err != nil {
 		return err
 	}
-
 	return sqlDB.Close()
 }
 
-func connectSqlx() (*sqlxDB, error) {
-	db, err := sqlx.Connect("mysql", mysqlDSN)
-	if err != nil {
-		return nil, err
-	}
-
-	return &sqlxDB{client: db}, err
-}
-
-func disconnectSqlx(db *sqlxDB) error {
-	return db.client.Close()
-}
+// TestMigrationIssue_gorm_success shows that gorm, unlike sqlx, scans by
+// field name and simply ignores columns its destination struct doesn't
+// have a field for, so it needs no Unsafe()-style escape hatch.
+func TestMigrationIssue_gorm_success(t *testing.T) {
+	mysqlBackend := backends()[0] // mysql is always first, see backends()
+	dsn := mysqlBackend.start(t)
 
-func TestMigrationIssue_sqlx_failure(t *testing.T) {
-	unsafe := false
-
-	// initial schema with 5 columns
-	// +----+-----------+-----------+---------------------+---------------------+
-	// | id | full_name | address   | created_at          | updated_at          |
-	// +----+-----------+-----------+---------------------+---------------------+
-	// |  1 | John Doe  | Singapore | 2022-06-14 11:36:41 | 2022-06-14 11:36:41 |
-	// +----+-----------+-----------+---------------------+---------------------+
-	db, err := connectSqlx()
-	require.NoError(t, err)
-	require.NotNil(t, db)
-	t.Cleanup(func() { require.NoError(t, disconnectSqlx(db)) })
+	driver, ok := db.For(mysqlBackend.dialect)
+	require.True(t, ok)
 
-	// query unsafely
-	users, err := db.ListUsers(unsafe)
+	gdb, err := connectGorm(dsn)
 	require.NoError(t, err)
-	require.Len(t, users, 1)
+	require.NotNil(t, gdb)
+	t.Cleanup(func() { require.NoError(t, disconnectGorm(gdb)) })
 
-	user := users[0]
-	require.Equal(t, "John Doe", user.FullName)
-	require.Equal(t, "Singapore", user.Address)
-
-	// migrate up, add a new column `phone_number` in to `auth.users` table
-	// at this moment, some instances of application are still having the old versions,
-	// they have to be fully compatible with new schema after the up migration is done,
-	// otherwise there will be runtime error happening for the queries
-	// +----+-----------+-----------+---------------------+---------------------+---------------|
-	// | id | full_name | address   | created_at          | updated_at          | phone_number  |
-	// +----+-----------+-----------+---------------------+---------------------+---------------|
-	// |  1 | John Doe  | Singapore | 2022-06-14 11:36:41 | 2022-06-14 11:36:41 |               |
-	// +----+-----------+-----------+---------------------+---------------------+---------------|
-	require.NoError(t, db.MigrateUp())
-	t.Cleanup(func() { require.NoError(t, db.MigrateDown()) })
-
-	// select again, simulate real traffic, this returns error due to a strict rule of sqlx
-	// by default, unsafe is false so sqlx return error to the application
-	// with sqlx, we can solve by setting unsafe to true (calling Unsafe() func)
-	// see TestMigrationIssue_sqlx_success for more information
-	users, err = db.ListUsers(unsafe)
-	require.Error(t, err)
-	require.Equal(t, "missing destination name phone_number in *main_test.User", err.Error())
-}
-
-func TestMigrationIssue_sqlx_success(t *testing.T) {
-	unsafe := true
-	db, err := connectSqlx()
+	sqlDB, err := gdb.client.DB()
 	require.NoError(t, err)
-	require.NotNil(t, db)
-	t.Cleanup(func() { require.NoError(t, disconnectSqlx(db)) })
+	conn := db.Wrap(sqlDB)
 
-	users, err := db.ListUsers(unsafe)
+	_, err = conn.DB().Exec(mysqlBackend.createTable)
 	require.NoError(t, err)
-	require.Len(t, users, 1)
-
-	user := users[0]
-	require.Equal(t, "John Doe", user.FullName)
-	require.Equal(t, "Singapore", user.Address)
-
-	// migrate up
-	require.NoError(t, db.MigrateUp())
-	t.Cleanup(func() { require.NoError(t, db.MigrateDown()) })
-
-	// select again, simulate real traffic
-	users, err = db.ListUsers(unsafe)
-	require.NoError(t, err)
-
-	user = users[0]
-	require.Equal(t, "John Doe", user.FullName)
-	require.Equal(t, "Singapore", user.Address)
-}
-
-func TestMigrationIssue_gorm_success(t *testing.T) {
-	db, err := connectGorm()
+	_, err = conn.DB().Exec(`INSERT INTO users (full_name, address) VALUES ('John Doe', 'Singapore')`)
 	require.NoError(t, err)
-	require.NotNil(t, db)
-	t.Cleanup(func() { require.NoError(t, disconnectGorm(db)) })
 
-	users, err := db.ListUsers()
+	users, err := gdb.ListUsers()
 	require.NoError(t, err)
 	require.Len(t, users, 1)
 
@@ -198,12 +207,10 @@ func TestMigrationIssue_gorm_success(t *testing.T) {
 	require.Equal(t, "John Doe", user.FullName)
 	require.Equal(t, "Singapore", user.Address)
 
-	// migrate up
-	require.NoError(t, db.MigrateUp())
-	t.Cleanup(func() { require.NoError(t, db.MigrateDown()) })
+	require.NoError(t, driver.AddColumn(conn, "users", "phone_number", mysqlBackend.phoneNumber))
+	t.Cleanup(func() { require.NoError(t, driver.DropColumn(conn, "users", "phone_number")) })
 
-	// select again, simulate real traffic
-	users, err = db.ListUsers()
+	users, err = gdb.ListUsers()
 	require.NoError(t, err)
 
 	user = users[0]
@@ -0,0 +1,91 @@
+// Package db abstracts the demo's database access behind a Driver
+// interface so the migration-compatibility behavior this repo exists to
+// show (sqlx's strict-mode surprise on a newly added column) can be
+// exercised against MySQL, Postgres, and SQLite rather than only a
+// locally-running MySQL.
+package db
+
+import "database/sql"
+
+// Dialect names a supported SQL dialect.
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite3  Dialect = "sqlite3"
+)
+
+// Conn is an open database connection returned by Driver.Connect.
+type Conn interface {
+	DB() *sql.DB
+	Close() error
+}
+
+// Driver abstracts the parts of schema management that differ across SQL
+// dialects: connecting, quoting identifiers, and adding/dropping a column.
+// AddColumn/DropColumn take the Conn (rather than just returning SQL text)
+// because SQLite can't DROP COLUMN directly on older versions and instead
+// has to rebuild the table, which takes more than one statement.
+type Driver interface {
+	Connect(dsn string) (Conn, error)
+	Dialect() Dialect
+	QuoteIdent(ident string) string
+	AddColumn(conn Conn, table, column, spec string) error
+	DropColumn(conn Conn, table, column string) error
+}
+
+// Drivers registered by dialect name, populated by each driver's init().
+var drivers = map[Dialect]Driver{}
+
+// Register adds a Driver under its dialect, for use by Open. Driver
+// implementations call this from init().
+func Register(d Driver) {
+	drivers[d.Dialect()] = d
+}
+
+// Open connects to dsn using the registered driver for dialect.
+func Open(dialect Dialect, dsn string) (Conn, error) {
+	d, ok := For(dialect)
+	if !ok {
+		return nil, &UnsupportedDialectError{Dialect: dialect}
+	}
+	return d.Connect(dsn)
+}
+
+// For returns the registered Driver for dialect, if any.
+func For(dialect Dialect) (Driver, bool) {
+	d, ok := drivers[dialect]
+	return d, ok
+}
+
+// Wrap adapts an already-open *sql.DB (e.g. one owned by an sqlx.DB or
+// gorm.DB) into a Conn, so AddColumn/DropColumn can run against a caller's
+// existing connection pool instead of opening a second one. A second pool
+// to the same SQLite file can race with the first: a statement prepared on
+// one connection doesn't see DDL committed by another until its own schema
+// cache is invalidated, which is exactly the kind of surprise this package
+// exists to avoid.
+func Wrap(sqlDB *sql.DB) Conn {
+	return &conn{db: sqlDB}
+}
+
+// UnsupportedDialectError is returned by Open for a dialect with no
+// registered Driver.
+type UnsupportedDialectError struct {
+	Dialect Dialect
+}
+
+func (e *UnsupportedDialectError) Error() string {
+	return "db: unsupported dialect " + string(e.Dialect)
+}
+
+// conn is the straightforward Conn implementation returned by every
+// driver's Connect and by Wrap; the dialect-specific add/drop column logic
+// lives on the Driver, not here.
+type conn struct {
+	db *sql.DB
+}
+
+func (c *conn) DB() *sql.DB  { return c.db }
+func (c *conn) Close() error { return c.db.Close() }
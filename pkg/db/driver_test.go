@@ -0,0 +1,72 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tranngoclam/go-database-migration/pkg/db"
+)
+
+func TestOpen_sqlite3(t *testing.T) {
+	conn, err := db.Open(db.SQLite3, ":memory:")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestOpen_unsupportedDialect(t *testing.T) {
+	_, err := db.Open("oracle", "dsn")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported dialect")
+}
+
+func TestQuoteIdent(t *testing.T) {
+	cases := []struct {
+		dialect db.Dialect
+		want    string
+	}{
+		{db.MySQL, "`users`"},
+		{db.Postgres, `"users"`},
+		{db.SQLite3, `"users"`},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.dialect), func(t *testing.T) {
+			driver, ok := db.For(tc.dialect)
+			require.True(t, ok)
+			require.Equal(t, tc.want, driver.QuoteIdent("users"))
+		})
+	}
+}
+
+// TestAddDropColumn_sqlite3 exercises the add/drop column lifecycle against
+// a real (in-memory) SQLite database, the only one of the three dialects
+// that runs without an external server.
+func TestAddDropColumn_sqlite3(t *testing.T) {
+	conn, err := db.Open(db.SQLite3, ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, conn.Close()) })
+
+	driver, ok := db.For(db.SQLite3)
+	require.True(t, ok)
+
+	_, err = conn.DB().Exec(`CREATE TABLE users (id INTEGER, full_name TEXT, address TEXT)`)
+	require.NoError(t, err)
+	_, err = conn.DB().Exec(`INSERT INTO users (id, full_name, address) VALUES (1, 'John Doe', 'Singapore')`)
+	require.NoError(t, err)
+
+	require.NoError(t, driver.AddColumn(conn, "users", "phone_number", "TEXT"))
+
+	var phoneNumber *string
+	require.NoError(t, conn.DB().QueryRow(`SELECT phone_number FROM users WHERE id = 1`).Scan(&phoneNumber))
+	require.Nil(t, phoneNumber)
+
+	require.NoError(t, driver.DropColumn(conn, "users", "phone_number"))
+
+	rows, err := conn.DB().Query(`SELECT * FROM users`)
+	require.NoError(t, err)
+	defer rows.Close()
+	cols, err := rows.Columns()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"id", "full_name", "address"}, cols)
+}
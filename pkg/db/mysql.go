@@ -0,0 +1,42 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func init() {
+	Register(&mysqlDriver{})
+}
+
+type mysqlDriver struct{}
+
+func (mysqlDriver) Dialect() Dialect { return MySQL }
+
+func (mysqlDriver) Connect(dsn string) (Conn, error) {
+	sqlDB, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("db: connect mysql: %w", err)
+	}
+	return &conn{db: sqlDB}, nil
+}
+
+func (mysqlDriver) QuoteIdent(ident string) string {
+	return "`" + ident + "`"
+}
+
+func (d mysqlDriver) AddColumn(c Conn, table, column, spec string) error {
+	_, err := c.DB().Exec(fmt.Sprintf(
+		`ALTER TABLE %s ADD COLUMN %s %s`, d.QuoteIdent(table), d.QuoteIdent(column), spec,
+	))
+	return err
+}
+
+func (d mysqlDriver) DropColumn(c Conn, table, column string) error {
+	_, err := c.DB().Exec(fmt.Sprintf(
+		`ALTER TABLE %s DROP COLUMN %s`, d.QuoteIdent(table), d.QuoteIdent(column),
+	))
+	return err
+}
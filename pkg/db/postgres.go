@@ -0,0 +1,42 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq"
+)
+
+func init() {
+	Register(&postgresDriver{})
+}
+
+type postgresDriver struct{}
+
+func (postgresDriver) Dialect() Dialect { return Postgres }
+
+func (postgresDriver) Connect(dsn string) (Conn, error) {
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("db: connect postgres: %w", err)
+	}
+	return &conn{db: sqlDB}, nil
+}
+
+func (postgresDriver) QuoteIdent(ident string) string {
+	return `"` + ident + `"`
+}
+
+func (d postgresDriver) AddColumn(c Conn, table, column, spec string) error {
+	_, err := c.DB().Exec(fmt.Sprintf(
+		`ALTER TABLE %s ADD COLUMN %s %s`, d.QuoteIdent(table), d.QuoteIdent(column), spec,
+	))
+	return err
+}
+
+func (d postgresDriver) DropColumn(c Conn, table, column string) error {
+	_, err := c.DB().Exec(fmt.Sprintf(
+		`ALTER TABLE %s DROP COLUMN %s`, d.QuoteIdent(table), d.QuoteIdent(column),
+	))
+	return err
+}
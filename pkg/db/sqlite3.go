@@ -0,0 +1,104 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	Register(&sqlite3Driver{})
+}
+
+type sqlite3Driver struct{}
+
+func (sqlite3Driver) Dialect() Dialect { return SQLite3 }
+
+func (sqlite3Driver) Connect(dsn string) (Conn, error) {
+	sqlDB, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("db: connect sqlite3: %w", err)
+	}
+	return &conn{db: sqlDB}, nil
+}
+
+func (sqlite3Driver) QuoteIdent(ident string) string {
+	return `"` + ident + `"`
+}
+
+func (d sqlite3Driver) AddColumn(c Conn, table, column, spec string) error {
+	_, err := c.DB().Exec(fmt.Sprintf(
+		`ALTER TABLE %s ADD COLUMN %s %s`, d.QuoteIdent(table), d.QuoteIdent(column), spec,
+	))
+	return err
+}
+
+// DropColumn rebuilds table without column, since SQLite only gained a
+// native ALTER TABLE ... DROP COLUMN in 3.35 (2021) and plenty of deployed
+// builds (notably the one bundled with mattn/go-sqlite3) predate that. The
+// rebuild keeps the remaining columns' data but, being a plain
+// CREATE TABLE ... AS SELECT, does not preserve constraints like PRIMARY
+// KEY or UNIQUE on the rebuilt table.
+func (d sqlite3Driver) DropColumn(c Conn, table, column string) error {
+	remaining, err := d.columnsExcept(c, table, column)
+	if err != nil {
+		return err
+	}
+
+	tx, err := c.DB().Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tmp := table + "_modelgen_tmp"
+	quotedCols := make([]string, len(remaining))
+	for i, col := range remaining {
+		quotedCols[i] = d.QuoteIdent(col)
+	}
+	colList := strings.Join(quotedCols, ", ")
+
+	if _, err := tx.Exec(fmt.Sprintf(
+		`CREATE TABLE %s AS SELECT %s FROM %s`, d.QuoteIdent(tmp), colList, d.QuoteIdent(table),
+	)); err != nil {
+		return fmt.Errorf("db: rebuild table without %s: %w", column, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`DROP TABLE %s`, d.QuoteIdent(table))); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf(
+		`ALTER TABLE %s RENAME TO %s`, d.QuoteIdent(tmp), d.QuoteIdent(table),
+	)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (sqlite3Driver) columnsExcept(c Conn, table, column string) ([]string, error) {
+	rows, err := c.DB().Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return nil, fmt.Errorf("db: inspect columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var (
+			cid        int
+			name, ctyp string
+			notNull    bool
+			dflt       sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &ctyp, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		if name != column {
+			cols = append(cols, name)
+		}
+	}
+	return cols, rows.Err()
+}
@@ -0,0 +1,74 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+)
+
+// lockName is the name used for the database-level lock that serializes
+// concurrent Migrator runs, e.g. two instances of a service deploying at
+// the same time.
+const lockName = "migration_lock"
+
+// acquireLock blocks (on MySQL up to m.lockTimeout, on Postgres until ctx is
+// done) until the migration lock is held, and returns the release function alongside
+// the *sql.Conn it was acquired on. MySQL's GET_LOCK and Postgres's
+// pg_advisory_lock are session-scoped, not transaction- or statement-scoped,
+// so acquiring through the pooled *sql.DB is unsafe: database/sql is free to
+// hand the connection back to the pool as soon as the acquiring Exec
+// returns, and a concurrent Migrator's acquire call can then land on that
+// same physical session, where Postgres/MySQL treat it as the same session
+// re-acquiring its own lock rather than blocking. Pinning a single *sql.Conn
+// for the whole acquire/release lifetime avoids that. The caller must keep
+// the returned conn open (and call release, which closes it) for as long as
+// the lock needs to be held.
+func (m *Migrator) acquireLock(ctx context.Context) (conn *sql.Conn, release func(ctx context.Context) error, err error) {
+	conn, err = m.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("migration: acquire lock connection: %w", err)
+	}
+
+	switch m.dialect {
+	case Postgres:
+		key := hashLockName(lockName)
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, key); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("migration: pg_advisory_lock: %w", err)
+		}
+		return conn, func(ctx context.Context) error {
+			_, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, key)
+			if closeErr := conn.Close(); err == nil {
+				err = closeErr
+			}
+			return err
+		}, nil
+	default: // MySQL
+		var acquired int
+		row := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, int(m.lockTimeout.Seconds()))
+		if err := row.Scan(&acquired); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("migration: GET_LOCK: %w", err)
+		}
+		if acquired != 1 {
+			conn.Close()
+			return nil, nil, fmt.Errorf("migration: timed out acquiring %q", lockName)
+		}
+		return conn, func(ctx context.Context) error {
+			_, err := conn.ExecContext(ctx, `SELECT RELEASE_LOCK(?)`, lockName)
+			if closeErr := conn.Close(); err == nil {
+				err = closeErr
+			}
+			return err
+		}, nil
+	}
+}
+
+// hashLockName turns the lock name into the int64 key pg_advisory_lock
+// expects.
+func hashLockName(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
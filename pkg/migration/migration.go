@@ -0,0 +1,43 @@
+// Package migration is a minimal migration subsystem: migrations are
+// registered from init() functions, one per file, and applied in order by a
+// Migrator that records progress in a schema_migrations table and guards
+// concurrent deployments with a database-level lock.
+package migration
+
+import (
+	"database/sql"
+	"sort"
+)
+
+// Migration is a single forward/backward schema change. Up and Down run
+// inside the same transaction the Migrator uses to record the version, so
+// on Postgres either both the DDL and the bookkeeping commit, or neither
+// does. MySQL commits DDL implicitly, so there a DDL step that succeeded
+// stays applied even if the bookkeeping afterwards fails.
+type Migration struct {
+	ID          string
+	Description string
+	Up          func(tx *sql.Tx) error
+	Down        func(tx *sql.Tx) error
+}
+
+// migrations is populated by Register calls from each migration file's
+// init() function. IDs are sortable timestamps (e.g. "20220614113641"), so
+// sorting migrations by ID also sorts them chronologically.
+var migrations []*Migration
+
+// Register adds a migration to the package-level registry. Migration files
+// call this from init(), one migration per file.
+func Register(m *Migration) {
+	migrations = append(migrations, m)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].ID < migrations[j].ID
+	})
+}
+
+// All returns the registered migrations in ID order.
+func All() []*Migration {
+	out := make([]*Migration, len(migrations))
+	copy(out, migrations)
+	return out
+}
@@ -0,0 +1,18 @@
+package migration_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tranngoclam/go-database-migration/pkg/migration"
+	_ "github.com/tranngoclam/go-database-migration/pkg/migration/migrations"
+)
+
+func TestAll_sortedByID(t *testing.T) {
+	all := migration.All()
+	require.NotEmpty(t, all)
+
+	for i := 1; i < len(all); i++ {
+		require.Less(t, all[i-1].ID, all[i].ID, "migrations must be sorted by ID")
+	}
+}
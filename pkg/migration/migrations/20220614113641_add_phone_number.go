@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/tranngoclam/go-database-migration/pkg/migration"
+)
+
+func init() {
+	migration.Register(&migration.Migration{
+		ID:          "20220614113641",
+		Description: "add phone_number to users",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`ALTER TABLE users ADD COLUMN phone_number VARCHAR(127)`)
+			return err
+		},
+		Down: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`ALTER TABLE users DROP COLUMN phone_number`)
+			return err
+		},
+	})
+}
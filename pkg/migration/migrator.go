@@ -0,0 +1,283 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Dialect identifies the SQL dialect a Migrator talks to, since the lock
+// primitive (GET_LOCK vs pg_advisory_lock) and DDL syntax for the
+// schema_migrations table differ between them.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+)
+
+// defaultLockTimeout bounds how long a MySQL Migrator waits in GET_LOCK for
+// another instance to release the migration lock before giving up. Postgres's
+// pg_advisory_lock does not use it and waits until ctx is done.
+const defaultLockTimeout = 15 * time.Second
+
+// Migrator applies the registered migrations against db in order, tracking
+// progress in a schema_migrations table and serializing concurrent runs
+// with a database-level lock so that two deployments migrating at once
+// cannot race.
+type Migrator struct {
+	db          *sql.DB
+	dialect     Dialect
+	lockTimeout time.Duration
+}
+
+// New returns a Migrator bound to db using dialect's lock primitive and DDL
+// syntax, with the package's default lock-acquisition timeout.
+func New(db *sql.DB, dialect Dialect) *Migrator {
+	return &Migrator{db: db, dialect: dialect, lockTimeout: defaultLockTimeout}
+}
+
+// appliedVersion records whether a migration has been applied, and whether
+// it was left dirty by a failed run.
+type appliedVersion struct {
+	version int64
+	dirty   bool
+}
+
+// Up applies every registered migration that hasn't already been applied,
+// in ID order. If any previous run left the schema dirty, Up refuses to
+// proceed until Force clears it.
+func (m *Migrator) Up(ctx context.Context) error {
+	_, release, err := m.acquireLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(context.Background())
+
+	if err := m.ensureSchemaMigrationsTable(ctx); err != nil {
+		return err
+	}
+
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range applied {
+		if a.dirty {
+			return fmt.Errorf("migration: version %d is dirty, run Force before migrating further", a.version)
+		}
+	}
+
+	for _, mig := range All() {
+		version, err := strconv.ParseInt(mig.ID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("migration: migration ID %q is not a valid version: %w", mig.ID, err)
+		}
+		if _, ok := applied[version]; ok {
+			continue
+		}
+		if err := m.apply(ctx, version, mig.Up); err != nil {
+			return fmt.Errorf("migration: applying %s (%s): %w", mig.ID, mig.Description, err)
+		}
+	}
+	return nil
+}
+
+// Down rolls back the given number of already-applied migrations, most
+// recent first.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	_, release, err := m.acquireLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(context.Background())
+
+	if err := m.ensureSchemaMigrationsTable(ctx); err != nil {
+		return err
+	}
+
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range applied {
+		if a.dirty {
+			return fmt.Errorf("migration: version %d is dirty, run Force before rolling back", a.version)
+		}
+	}
+
+	all := All()
+	for i := len(all) - 1; i >= 0 && steps > 0; i-- {
+		version, err := strconv.ParseInt(all[i].ID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("migration: migration ID %q is not a valid version: %w", all[i].ID, err)
+		}
+		if _, ok := applied[version]; !ok {
+			continue
+		}
+		if err := m.revert(ctx, version, all[i].Down); err != nil {
+			return fmt.Errorf("migration: reverting %s (%s): %w", all[i].ID, all[i].Description, err)
+		}
+		steps--
+	}
+	return nil
+}
+
+// Force marks version as clean without running its Up or Down function,
+// for use after manually repairing a database left dirty by a failed
+// migration.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf(
+		`UPDATE schema_migrations SET dirty = %s WHERE version = %s`, m.falseLiteral(), m.placeholder(1),
+	), version)
+	if err != nil {
+		return fmt.Errorf("migration: force version %d: %w", version, err)
+	}
+	return nil
+}
+
+// apply runs mig.Up inside a transaction and records the version as
+// applied. A failure leaves the version recorded as dirty so subsequent
+// Up/Down calls refuse to run until Force clears it.
+func (m *Migrator) apply(ctx context.Context, version int64, up func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := up(tx); err != nil {
+		tx.Rollback()
+		m.markDirty(ctx, version)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+		`INSERT INTO schema_migrations (version, dirty, applied_at) VALUES (%s, %s, %s)`,
+		m.placeholder(1), m.falseLiteral(), m.nowFunc(),
+	), version); err != nil {
+		tx.Rollback()
+		m.markDirty(ctx, version)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		m.markDirty(ctx, version)
+		return err
+	}
+	return nil
+}
+
+// revert runs mig.Down inside a transaction and removes the version's
+// record. A failure leaves the version recorded as dirty.
+func (m *Migrator) revert(ctx context.Context, version int64, down func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := down(tx); err != nil {
+		tx.Rollback()
+		m.markDirty(ctx, version)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+		`DELETE FROM schema_migrations WHERE version = %s`, m.placeholder(1),
+	), version); err != nil {
+		tx.Rollback()
+		m.markDirty(ctx, version)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// markDirty best-effort flags version as dirty after a failed apply/revert.
+// It runs outside the failed transaction, which has already been rolled
+// back.
+func (m *Migrator) markDirty(ctx context.Context, version int64) {
+	_, _ = m.db.ExecContext(ctx, fmt.Sprintf(
+		`INSERT INTO schema_migrations (version, dirty, applied_at) VALUES (%s, %s, %s) `+m.onConflictMarkDirty(),
+		m.placeholder(1), m.trueLiteral(), m.nowFunc(),
+	), version)
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context) (map[int64]appliedVersion, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT version, dirty FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("migration: list applied versions: %w", err)
+	}
+	defer rows.Close()
+
+	out := map[int64]appliedVersion{}
+	for rows.Next() {
+		var a appliedVersion
+		if err := rows.Scan(&a.version, &a.dirty); err != nil {
+			return nil, fmt.Errorf("migration: scan applied version: %w", err)
+		}
+		out[a.version] = a
+	}
+	return out, rows.Err()
+}
+
+func (m *Migrator) ensureSchemaMigrationsTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, m.createSchemaMigrationsTableSQL())
+	if err != nil {
+		return fmt.Errorf("migration: ensure schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) createSchemaMigrationsTableSQL() string {
+	switch m.dialect {
+	case Postgres:
+		return `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			dirty BOOL NOT NULL DEFAULT false,
+			applied_at TIMESTAMP NOT NULL DEFAULT now()
+		)`
+	default: // MySQL
+		return `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			dirty BOOL NOT NULL DEFAULT false,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`
+	}
+}
+
+func (m *Migrator) placeholder(n int) string {
+	if m.dialect == Postgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+func (m *Migrator) falseLiteral() string {
+	if m.dialect == Postgres {
+		return "false"
+	}
+	return "0"
+}
+
+func (m *Migrator) trueLiteral() string {
+	if m.dialect == Postgres {
+		return "true"
+	}
+	return "1"
+}
+
+func (m *Migrator) nowFunc() string {
+	if m.dialect == Postgres {
+		return "now()"
+	}
+	return "CURRENT_TIMESTAMP"
+}
+
+func (m *Migrator) onConflictMarkDirty() string {
+	if m.dialect == Postgres {
+		return "ON CONFLICT (version) DO UPDATE SET dirty = " + m.trueLiteral()
+	}
+	return "ON DUPLICATE KEY UPDATE dirty = " + m.trueLiteral()
+}
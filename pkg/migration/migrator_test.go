@@ -0,0 +1,58 @@
+package migration_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+	gormmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/tranngoclam/go-database-migration/internal/dbtest"
+	"github.com/tranngoclam/go-database-migration/pkg/migration"
+	_ "github.com/tranngoclam/go-database-migration/pkg/migration/migrations"
+)
+
+// TestMigrator_parallel starts two Migrator instances, one backed by a
+// sqlx.DB and one by gorm's underlying *sql.DB, and runs Up concurrently
+// from both. The migration lock must serialize them: both calls should
+// succeed, and the migration must end up applied exactly once rather than
+// racing on schema_migrations.
+func TestMigrator_parallel(t *testing.T) {
+	mysqlDSN := dbtest.StartMySQL(t)
+
+	sqlxDB, err := sqlx.Connect("mysql", mysqlDSN)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, sqlxDB.Close()) })
+
+	// The registered migration alters users, which the fresh database lacks.
+	_, err = sqlxDB.Exec(`CREATE TABLE users (id BIGINT AUTO_INCREMENT PRIMARY KEY, full_name VARCHAR(255))`)
+	require.NoError(t, err)
+
+	gormDB, err := gorm.Open(gormmysql.Open(mysqlDSN), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := gormDB.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, sqlDB.Close()) })
+
+	a := migration.New(sqlxDB.DB, migration.MySQL)
+	b := migration.New(sqlDB, migration.MySQL)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	wg.Add(2)
+	go func() { defer wg.Done(); errs[0] = a.Up(context.Background()) }()
+	go func() { defer wg.Done(); errs[1] = b.Up(context.Background()) }()
+	wg.Wait()
+
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+
+	var count int
+	require.NoError(t, sqlxDB.Get(&count, `SELECT COUNT(*) FROM schema_migrations WHERE version = 20220614113641`))
+	require.Equal(t, 1, count)
+
+	t.Cleanup(func() { require.NoError(t, a.Down(context.Background(), 1)) })
+}
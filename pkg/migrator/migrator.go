@@ -0,0 +1,182 @@
+// Package migrator implements a zero-downtime "expand/contract" migration
+// runner in the style of pgroll: a migration exposes one versioned view per
+// schema version so that old and new application binaries can keep reading
+// and writing through the same table while a migration is in flight.
+//
+// A binary compiled against version N reads and writes through the view
+// named VersionedSchemaName(table, N) instead of the base table, so a
+// `SELECT *` from it only ever sees the columns that existed when it was
+// built — this is what would have kept TestMigrationIssue_sqlx_failure from
+// ever seeing an unexpected phone_number column. The views live in the base
+// table's own schema; no per-version schema is created, so pinning
+// search_path (or MySQL's default schema) to a version is not supported.
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Dialect identifies the SQL dialect a Migrator talks to. Start/Complete/
+// Rollback issue the same DDL for every dialect; only ActiveVersions' view
+// lookup differs ($1 vs ? placeholders).
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+)
+
+// Migration describes a single column addition, expanded across two
+// versioned views: Version (the schema before the change) and Version+1
+// (the schema after it).
+type Migration struct {
+	Table      string   // base table being migrated, e.g. "users"
+	Column     string   // new column being added, e.g. "phone_number"
+	ColumnSpec string   // column type/constraints, e.g. "VARCHAR(127)"
+	Columns    []string // full set of columns visible at Version, excluding Column
+	Version    int      // schema version this migration expands from
+}
+
+// Migrator drives the Start/Complete/Rollback lifecycle of a single
+// expand/contract migration against the versioned views it creates.
+type Migrator struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// New returns a Migrator that issues its DDL against db using dialect's
+// SQL flavor.
+func New(db *sql.DB, dialect Dialect) *Migrator {
+	return &Migrator{db: db, dialect: dialect}
+}
+
+// VersionedSchemaName returns the name of the view that exposes base as it
+// looked at the given schema version, e.g. VersionedSchemaName("users", 2)
+// -> "users_v2". Applications query this view by name so they only ever see
+// the columns their binary was compiled against.
+func VersionedSchemaName(base string, version int) string {
+	return fmt.Sprintf("%s_v%d", base, version)
+}
+
+// Start begins an expand/contract migration: it adds the new column to the
+// base table, then creates/updates two views, {table}_v{N} (the old shape,
+// for binaries still running the previous version) and {table}_v{N+1} (the
+// new shape). Writes through {table}_v{N} naturally leave the new column at
+// its default, so readers of {table}_v{N+1} never see a row missing it —
+// both Postgres and MySQL fill a column an auto-updatable view omits with
+// its default on INSERT, no trigger required.
+//
+// {table}_v{N} may already exist as the previous migration's {table}_v{N+1},
+// which its Start created and its Complete leaves in place, so Start
+// (re)defines it with CREATE OR REPLACE VIEW rather than CREATE VIEW, to
+// support the chain of successive migrations ActiveVersions is meant for.
+func (m *Migrator) Start(ctx context.Context, mig Migration) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migrator: begin start: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+		`ALTER TABLE %s ADD COLUMN %s %s`, mig.Table, mig.Column, mig.ColumnSpec,
+	)); err != nil {
+		return fmt.Errorf("migrator: add column: %w", err)
+	}
+
+	oldView := VersionedSchemaName(mig.Table, mig.Version)
+	newView := VersionedSchemaName(mig.Table, mig.Version+1)
+
+	oldCols := columnList(mig.Columns)
+	newCols := columnList(append(append([]string{}, mig.Columns...), mig.Column))
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+		`CREATE OR REPLACE VIEW %s AS SELECT %s FROM %s`, oldView, oldCols, mig.Table,
+	)); err != nil {
+		return fmt.Errorf("migrator: create old view %s: %w", oldView, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+		`CREATE VIEW %s AS SELECT %s FROM %s`, newView, newCols, mig.Table,
+	)); err != nil {
+		return fmt.Errorf("migrator: create new view %s: %w", newView, err)
+	}
+
+	return tx.Commit()
+}
+
+// Complete finishes a migration that has reached version+1: it drops the
+// old view so only the new schema remains.
+func (m *Migrator) Complete(ctx context.Context, table string, version int) error {
+	oldView := VersionedSchemaName(table, version)
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf(`DROP VIEW %s`, oldView)); err != nil {
+		return fmt.Errorf("migrator: drop view %s: %w", oldView, err)
+	}
+	return nil
+}
+
+// Rollback reverts a migration that was Start-ed but never Complete-d: it
+// drops the new view and removes the column it added, leaving only the
+// version-N view in place.
+func (m *Migrator) Rollback(ctx context.Context, mig Migration) error {
+	newView := VersionedSchemaName(mig.Table, mig.Version+1)
+
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf(`DROP VIEW %s`, newView)); err != nil {
+		return fmt.Errorf("migrator: drop view %s: %w", newView, err)
+	}
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf(
+		`ALTER TABLE %s DROP COLUMN %s`, mig.Table, mig.Column,
+	)); err != nil {
+		return fmt.Errorf("migrator: drop column: %w", err)
+	}
+	return nil
+}
+
+// ActiveVersions returns the schema versions currently exposed by a view,
+// i.e. the versions both old and new application binaries may be reading
+// from right now. After Complete the new version's view remains, so the
+// result then holds that single version; it is empty only when table has
+// no {table}_v{N} views at all.
+func (m *Migrator) ActiveVersions(ctx context.Context, table string) ([]int, error) {
+	query, pattern := m.viewLookupSQL()
+	rows, err := m.db.QueryContext(ctx, query, fmt.Sprintf(pattern, table))
+	if err != nil {
+		return nil, fmt.Errorf("migrator: list active versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("migrator: scan view name: %w", err)
+		}
+		var version int
+		if _, err := fmt.Sscanf(name, table+"_v%d", &version); err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+func columnList(cols []string) string {
+	out := ""
+	for i, c := range cols {
+		if i > 0 {
+			out += ", "
+		}
+		out += c
+	}
+	return out
+}
+
+func (m *Migrator) viewLookupSQL() (query, likePattern string) {
+	switch m.dialect {
+	case Postgres:
+		return `SELECT table_name FROM information_schema.views WHERE table_name LIKE $1`, "%s_v%%"
+	default:
+		return `SELECT table_name FROM information_schema.views WHERE table_name LIKE ?`, "%s_v%%"
+	}
+}
@@ -0,0 +1,167 @@
+package migrator_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tranngoclam/go-database-migration/internal/dbtest"
+	"github.com/tranngoclam/go-database-migration/pkg/migrator"
+)
+
+func TestVersionedSchemaName(t *testing.T) {
+	require.Equal(t, "users_v1", migrator.VersionedSchemaName("users", 1))
+	require.Equal(t, "users_v2", migrator.VersionedSchemaName("users", 2))
+}
+
+// TestMigrator_startComplete runs a migration through Start, writes
+// through the old view, reads the same row back through the new view, and
+// Completes it, then chains a second migration on the same table onto the
+// view the first one left behind, for every supported dialect. Rollback is
+// covered by TestMigrator_rollback.
+func TestMigrator_startComplete(t *testing.T) {
+	for _, b := range migratorBackends() {
+		b := b
+		t.Run(string(b.dialect), func(t *testing.T) {
+			db, err := sql.Open(b.driverName, b.start(t))
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, db.Close()) })
+
+			_, err = db.Exec(b.createTable)
+			require.NoError(t, err)
+
+			m := migrator.New(db, b.dialect)
+
+			mig1 := migrator.Migration{
+				Table:      "users",
+				Column:     "phone_number",
+				ColumnSpec: b.phoneNumber,
+				Columns:    []string{"id", "full_name"},
+				Version:    1,
+			}
+			require.NoError(t, m.Start(context.Background(), mig1))
+
+			oldView := migrator.VersionedSchemaName("users", 1)
+			newView := migrator.VersionedSchemaName("users", 2)
+
+			_, err = db.Exec(fmt.Sprintf(`INSERT INTO %s (full_name) VALUES ('John Doe')`, oldView))
+			require.NoError(t, err)
+
+			var fullName string
+			var phoneNumber sql.NullString
+			row := db.QueryRow(fmt.Sprintf(`SELECT full_name, phone_number FROM %s WHERE full_name = 'John Doe'`, newView))
+			require.NoError(t, row.Scan(&fullName, &phoneNumber))
+			require.Equal(t, "John Doe", fullName)
+			require.False(t, phoneNumber.Valid)
+
+			require.NoError(t, m.Complete(context.Background(), "users", 1))
+
+			versions, err := m.ActiveVersions(context.Background(), "users")
+			require.NoError(t, err)
+			require.Equal(t, []int{2}, versions)
+
+			// A second migration on the same table reuses users_v2 (left
+			// behind by mig1) as its old view instead of declaring it fresh.
+			mig2 := migrator.Migration{
+				Table:      "users",
+				Column:     "email",
+				ColumnSpec: "VARCHAR(255)",
+				Columns:    []string{"id", "full_name", "phone_number"},
+				Version:    2,
+			}
+			require.NoError(t, m.Start(context.Background(), mig2))
+
+			newView2 := migrator.VersionedSchemaName("users", 3)
+
+			_, err = db.Exec(fmt.Sprintf(`INSERT INTO %s (full_name) VALUES ('Jane Roe')`, newView))
+			require.NoError(t, err)
+
+			var email sql.NullString
+			row = db.QueryRow(fmt.Sprintf(`SELECT full_name, email FROM %s WHERE full_name = 'Jane Roe'`, newView2))
+			require.NoError(t, row.Scan(&fullName, &email))
+			require.Equal(t, "Jane Roe", fullName)
+			require.False(t, email.Valid)
+
+			require.NoError(t, m.Complete(context.Background(), "users", 2))
+
+			versions, err = m.ActiveVersions(context.Background(), "users")
+			require.NoError(t, err)
+			require.Equal(t, []int{3}, versions)
+		})
+	}
+}
+
+// TestMigrator_rollback starts a migration, then rolls it back instead of
+// completing it: the new view and added column should disappear, leaving
+// only the original view and shape in place.
+func TestMigrator_rollback(t *testing.T) {
+	for _, b := range migratorBackends() {
+		b := b
+		t.Run(string(b.dialect), func(t *testing.T) {
+			db, err := sql.Open(b.driverName, b.start(t))
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, db.Close()) })
+
+			_, err = db.Exec(b.createTable)
+			require.NoError(t, err)
+
+			mig := migrator.Migration{
+				Table:      "users",
+				Column:     "phone_number",
+				ColumnSpec: b.phoneNumber,
+				Columns:    []string{"id", "full_name"},
+				Version:    1,
+			}
+
+			m := migrator.New(db, b.dialect)
+			require.NoError(t, m.Start(context.Background(), mig))
+			require.NoError(t, m.Rollback(context.Background(), mig))
+
+			// Rollback only ever drops the new view and the column it
+			// added; the version-N view it started from is left in place.
+			versions, err := m.ActiveVersions(context.Background(), "users")
+			require.NoError(t, err)
+			require.Equal(t, []int{1}, versions)
+
+			oldView := migrator.VersionedSchemaName("users", 1)
+			_, err = db.Exec(fmt.Sprintf(`INSERT INTO %s (full_name) VALUES ('John Doe')`, oldView))
+			require.NoError(t, err)
+
+			newView := migrator.VersionedSchemaName("users", 2)
+			_, err = db.Exec(fmt.Sprintf(`SELECT 1 FROM %s`, newView))
+			require.Error(t, err, "rollback should have dropped %s", newView)
+		})
+	}
+}
+
+type migratorBackend struct {
+	dialect     migrator.Dialect
+	driverName  string
+	createTable string
+	phoneNumber string
+	start       func(t *testing.T) (dsn string)
+}
+
+func migratorBackends() []migratorBackend {
+	return []migratorBackend{
+		{
+			dialect:     migrator.MySQL,
+			driverName:  "mysql",
+			createTable: `CREATE TABLE users (id BIGINT AUTO_INCREMENT PRIMARY KEY, full_name VARCHAR(255))`,
+			phoneNumber: "VARCHAR(127)",
+			start:       dbtest.StartMySQL,
+		},
+		{
+			dialect:     migrator.Postgres,
+			driverName:  "postgres",
+			createTable: `CREATE TABLE users (id BIGSERIAL PRIMARY KEY, full_name VARCHAR(255))`,
+			phoneNumber: "VARCHAR(127)",
+			start:       dbtest.StartPostgres,
+		},
+	}
+}
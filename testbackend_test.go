@@ -0,0 +1,50 @@
+package main_test
+
+import (
+	"testing"
+
+	"github.com/tranngoclam/go-database-migration/internal/dbtest"
+	"github.com/tranngoclam/go-database-migration/pkg/db"
+)
+
+// backend describes one of the SQL dialects the migration-compatibility
+// suite runs against.
+type backend struct {
+	dialect     db.Dialect
+	driverName  string // database/sql driver name used by sqlx/gorm
+	createTable string
+	phoneNumber string // column spec passed to Driver.AddColumn
+	start       func(t *testing.T) (dsn string)
+}
+
+func backends() []backend {
+	return []backend{
+		{
+			dialect:     db.MySQL,
+			driverName:  "mysql",
+			createTable: `CREATE TABLE users (id BIGINT AUTO_INCREMENT PRIMARY KEY, full_name VARCHAR(255), address VARCHAR(255), created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+			phoneNumber: "VARCHAR(127)",
+			start:       dbtest.StartMySQL,
+		},
+		{
+			dialect:     db.Postgres,
+			driverName:  "postgres",
+			createTable: `CREATE TABLE users (id BIGSERIAL PRIMARY KEY, full_name VARCHAR(255), address VARCHAR(255), created_at TIMESTAMP DEFAULT now(), updated_at TIMESTAMP DEFAULT now())`,
+			phoneNumber: "VARCHAR(127)",
+			start:       dbtest.StartPostgres,
+		},
+		{
+			dialect:     db.SQLite3,
+			driverName:  "sqlite3",
+			createTable: `CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, full_name TEXT, address TEXT, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+			phoneNumber: "TEXT",
+			start:       startSQLite3,
+		},
+	}
+}
+
+// startSQLite3 needs no container: a file in the test's temp dir is a full
+// SQLite database.
+func startSQLite3(t *testing.T) string {
+	return t.TempDir() + "/users.db"
+}